@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+// clientFlags are the HTTP client flags shared by every subcommand that
+// talks to OverDrive.
+type clientFlags struct {
+	timeout        *time.Duration
+	connectTimeout *time.Duration
+	cookiesPath    *string
+}
+
+func registerClientFlags(flags *flag.FlagSet) clientFlags {
+	return clientFlags{
+		timeout:        flags.Duration("timeout", 0, "per-request timeout (0 disables)"),
+		connectTimeout: flags.Duration("connect-timeout", 0, "dial timeout (0 disables)"),
+		cookiesPath:    flags.String("cookies", "", "file to load/save cookies from/to as JSON"),
+	}
+}
+
+func (clientFlags clientFlags) config() httpclient.Config {
+	return httpclient.Config{
+		Timeout:        *clientFlags.timeout,
+		ConnectTimeout: *clientFlags.connectTimeout,
+		CookiesPath:    *clientFlags.cookiesPath,
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/arnavdugar/odm/internal/download"
+	"github.com/arnavdugar/odm/internal/httpclient"
+	"github.com/arnavdugar/odm/internal/odm"
+)
+
+func runFetchOdm(args []string) error {
+	flags := flag.NewFlagSet("fetch-odm", flag.ExitOnError)
+	filename := flags.String("f", "", ".odm file")
+	outputDirectory := flags.String("o", ".", "output directory")
+	rateInterval := flags.String("i", "2s", "rate interval")
+	retryCount := flags.Int("r", 3, "retry count")
+	workerCount := flags.Int("j", 1, "number of parallel downloads")
+	checksumsFile := flags.String("checksums", "", "file of name<TAB>hex checksums to verify against")
+	progressInterval := flags.Duration(
+		"progress-interval", 2*time.Second, "how often to print download progress")
+	assembleMode := flags.String("assemble", "",
+		`post-download assembly: "id3" to tag each part, "m4b" to concatenate into a chaptered m4b`)
+	clientFlags := registerClientFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *filename == "" {
+		return fmt.Errorf("odm file required")
+	}
+
+	media, err := odm.Load(*filename)
+	if err != nil {
+		return err
+	}
+
+	if err := media.Validate(); err != nil {
+		return err
+	}
+
+	interval, err := time.ParseDuration(*rateInterval)
+	if err != nil {
+		return err
+	}
+
+	var manifest download.Manifest
+	if *checksumsFile != "" {
+		manifest, err = download.LoadManifest(*checksumsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := httpclient.New(clientFlags.config())
+	if err != nil {
+		return err
+	}
+
+	session := odm.NewSession(client)
+
+	license, err := session.AcquireLicense(media)
+	if err != nil {
+		return err
+	}
+
+	parts := media.Formats[0].Parts.Part
+	attempts := make([]download.Attempt, len(parts))
+	for index, part := range parts {
+		partRequest, err := session.DownloadPart(media.Formats[0].Protocols[0].BaseUrl, part, license)
+		if err != nil {
+			return err
+		}
+
+		attempts[index] = download.Attempt{
+			Count:   0,
+			Key:     strconv.FormatUint(uint64(part.Number), 10),
+			Name:    fmt.Sprintf("%s.mp3", part.Name),
+			Request: partRequest,
+		}
+	}
+
+	tracker := download.NewProgressTracker()
+	done := make(chan struct{})
+	go tracker.Run(*progressInterval, done)
+	defer close(done)
+
+	downloader := &download.Downloader{
+		Client:     session.Client,
+		Limiter:    download.NewRateLimiter(interval),
+		Tracker:    tracker,
+		Manifest:   manifest,
+		OutputDir:  *outputDirectory,
+		RetryCount: *retryCount,
+	}
+
+	if err := downloader.All(*workerCount, attempts); err != nil {
+		return err
+	}
+
+	if err := client.SaveCookies(); err != nil {
+		return err
+	}
+
+	if *assembleMode == "" {
+		return nil
+	}
+
+	metadata, err := odm.ParseMetadata(media.Metadata)
+	if err != nil {
+		return fmt.Errorf("parsing metadata: %w", err)
+	}
+
+	coverPath, err := odm.FetchCover(client, metadata.CoverUrl, *outputDirectory)
+	if err != nil {
+		return fmt.Errorf("fetching cover: %w", err)
+	}
+
+	switch *assembleMode {
+	case "id3":
+		return odm.TagParts(*outputDirectory, parts, metadata, coverPath)
+	case "m4b":
+		return odm.AssembleM4B(*outputDirectory, parts, metadata, coverPath)
+	default:
+		return fmt.Errorf("unknown assemble mode: %s", *assembleMode)
+	}
+}
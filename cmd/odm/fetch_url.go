@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arnavdugar/odm/internal/download"
+	"github.com/arnavdugar/odm/internal/libby"
+)
+
+func runFetchUrl(args []string) error {
+	flags := flag.NewFlagSet("fetch-url", flag.ExitOnError)
+	overDriveUrl := flags.String("u", "", "url")
+	outputDirectory := flags.String("o", ".", "output directory")
+	rateInterval := flags.String("i", "2s", "rate interval")
+	retryCount := flags.Int("r", 3, "retry count")
+	workerCount := flags.Int("j", 1, "number of parallel downloads")
+	checksumsFile := flags.String("checksums", "", "file of name<TAB>hex checksums to verify against")
+	progressInterval := flags.Duration(
+		"progress-interval", 2*time.Second, "how often to print download progress")
+	clientFlags := registerClientFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *overDriveUrl == "" {
+		return fmt.Errorf("odm url required")
+	}
+
+	interval, err := time.ParseDuration(*rateInterval)
+	if err != nil {
+		return err
+	}
+
+	var manifest download.Manifest
+	if *checksumsFile != "" {
+		manifest, err = download.LoadManifest(*checksumsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := libby.NewClient(clientFlags.config())
+	if err != nil {
+		return err
+	}
+
+	dataBytes, data, response, err := libby.Fetch(client, *overDriveUrl)
+	if err != nil {
+		return err
+	}
+
+	if err := libby.SaveData(dataBytes, *outputDirectory); err != nil {
+		return err
+	}
+
+	attempts := make([]download.Attempt, len(data.Spine))
+	for index, spine := range data.Spine {
+		request, err := http.NewRequest("GET", libby.PartUrl(response, spine), nil)
+		if err != nil {
+			return err
+		}
+		request.Header.Set("Referer", response.Request.URL.String())
+		request.Header.Set("User-Agent", "nobody")
+
+		attempts[index] = download.Attempt{
+			Count:   0,
+			Key:     strconv.Itoa(index + 1),
+			Name:    spine.OriginalPath,
+			Request: request,
+		}
+	}
+
+	tracker := download.NewProgressTracker()
+	done := make(chan struct{})
+	go tracker.Run(*progressInterval, done)
+	defer close(done)
+
+	downloader := &download.Downloader{
+		Client:     client,
+		Limiter:    download.NewRateLimiter(interval),
+		Tracker:    tracker,
+		Manifest:   manifest,
+		OutputDir:  *outputDirectory,
+		RetryCount: *retryCount,
+	}
+
+	if err := downloader.All(*workerCount, attempts); err != nil {
+		return err
+	}
+
+	return client.SaveCookies()
+}
@@ -0,0 +1,33 @@
+// Command odm fetches, returns, and packages OverDrive audiobook loans.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: odm <fetch-odm|fetch-url|return|package> [flags]")
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "fetch-odm":
+		err = runFetchOdm(os.Args[2:])
+	case "fetch-url":
+		err = runFetchUrl(os.Args[2:])
+	case "return":
+		err = runReturn(os.Args[2:])
+	case "package":
+		err = runPackage(os.Args[2:])
+	default:
+		err = fmt.Errorf("unknown subcommand: %s", os.Args[1])
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/arnavdugar/odm/internal/archive"
+)
+
+func runPackage(args []string) error {
+	flags := flag.NewFlagSet("package", flag.ExitOnError)
+	outputDirectory := flags.String("o", ".", "directory to package")
+	destination := flags.String("output", "", "path of the zip file to create")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *destination == "" {
+		return fmt.Errorf("-output required")
+	}
+
+	if err := archive.Zip(*outputDirectory, *destination); err != nil {
+		return err
+	}
+
+	log.Printf("packaged %s into %s\n", *outputDirectory, *destination)
+	return nil
+}
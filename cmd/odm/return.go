@@ -0,0 +1,45 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+	"github.com/arnavdugar/odm/internal/odm"
+)
+
+func runReturn(args []string) error {
+	flags := flag.NewFlagSet("return", flag.ExitOnError)
+	filename := flags.String("f", "", ".odm file")
+	clientFlags := registerClientFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *filename == "" {
+		return fmt.Errorf("odm file required")
+	}
+
+	media, err := odm.Load(*filename)
+	if err != nil {
+		return err
+	}
+
+	client, err := httpclient.New(clientFlags.config())
+	if err != nil {
+		return err
+	}
+
+	session := odm.NewSession(client)
+	if err := session.EarlyReturn(media); err != nil {
+		return err
+	}
+
+	if err := client.SaveCookies(); err != nil {
+		return err
+	}
+
+	log.Println("loan returned successfully")
+	return nil
+}
@@ -0,0 +1,68 @@
+package archive
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZip(t *testing.T) {
+	sourceDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(sourceDir, "a.txt"), []byte("a contents"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(sourceDir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "sub", "b.txt"), []byte("b contents"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "out.zip")
+	if err := Zip(sourceDir, destPath); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.OpenReader(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	contents := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[file.Name] = string(data)
+	}
+
+	want := map[string]string{
+		"a.txt":     "a contents",
+		"sub/b.txt": "b contents",
+	}
+	if len(contents) != len(want) {
+		t.Fatalf("got entries %v, want %v", contents, want)
+	}
+	for name, data := range want {
+		if contents[name] != data {
+			t.Errorf("entry %s: got %q, want %q", name, contents[name], data)
+		}
+	}
+}
+
+func TestZip_NoSourceDir(t *testing.T) {
+	destPath := filepath.Join(t.TempDir(), "out.zip")
+	if err := Zip(filepath.Join(t.TempDir(), "does-not-exist"), destPath); err == nil {
+		t.Fatal("expected an error for a missing source directory")
+	}
+}
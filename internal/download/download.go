@@ -0,0 +1,431 @@
+// Package download implements a retrying, rate-limited, resumable file
+// downloader shared by the odm and url tools.
+package download
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+// ErrFailed is returned by a download when the server reports that the
+// content is not available and the attempt should be retried.
+var ErrFailed = errors.New("download failed")
+
+// RateLimiter is a token bucket shared across download workers, so a
+// configured interval still means one request every interval regardless of
+// how many workers are running concurrently.
+type RateLimiter struct {
+	tokens chan struct{}
+}
+
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	limiter := &RateLimiter{
+		tokens: make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			limiter.tokens <- struct{}{}
+		}
+	}()
+
+	return limiter
+}
+
+func (limiter *RateLimiter) Take() {
+	<-limiter.tokens
+}
+
+// CountingWriter wraps an io.Writer and atomically tracks the number of
+// bytes written to it, so progress can be read from another goroutine.
+type CountingWriter struct {
+	Writer  io.Writer
+	written int64
+}
+
+func (writer *CountingWriter) Write(data []byte) (int, error) {
+	n, err := writer.Writer.Write(data)
+	atomic.AddInt64(&writer.written, int64(n))
+	return n, err
+}
+
+func (writer *CountingWriter) Written() int64 {
+	return atomic.LoadInt64(&writer.written)
+}
+
+// WorkerProgress is the in-flight state of a single download attempt.
+type WorkerProgress struct {
+	Name       string
+	Downloaded *CountingWriter
+	Total      int64
+}
+
+// ProgressTracker reports per-worker and aggregate download progress on a
+// timer, rather than on every write, to avoid flooding stderr.
+type ProgressTracker struct {
+	mutex   sync.Mutex
+	workers map[string]*WorkerProgress
+}
+
+func NewProgressTracker() *ProgressTracker {
+	return &ProgressTracker{
+		workers: make(map[string]*WorkerProgress),
+	}
+}
+
+func (tracker *ProgressTracker) Start(key string, name string, total int64) *CountingWriter {
+	writer := &CountingWriter{}
+
+	tracker.mutex.Lock()
+	tracker.workers[key] = &WorkerProgress{
+		Name:       name,
+		Downloaded: writer,
+		Total:      total,
+	}
+	tracker.mutex.Unlock()
+
+	return writer
+}
+
+func (tracker *ProgressTracker) Finish(key string) {
+	tracker.mutex.Lock()
+	delete(tracker.workers, key)
+	tracker.mutex.Unlock()
+}
+
+func (tracker *ProgressTracker) Run(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tracker.print()
+		case <-done:
+			return
+		}
+	}
+}
+
+func (tracker *ProgressTracker) print() {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	if len(tracker.workers) == 0 {
+		return
+	}
+
+	var total int64
+	for key, worker := range tracker.workers {
+		downloaded := worker.Downloaded.Written()
+		total += downloaded
+
+		if worker.Total > 0 {
+			log.Printf("%s: %s: %d/%d bytes\n", key, worker.Name, downloaded, worker.Total)
+		} else {
+			log.Printf("%s: %s: %d bytes\n", key, worker.Name, downloaded)
+		}
+	}
+
+	log.Printf("total downloaded: %d bytes\n", total)
+}
+
+// Checksum is a single expected hash from a checksums manifest.
+type Checksum struct {
+	Algorithm string
+	Hex       string
+}
+
+// Manifest maps a file name to its expected checksum, as loaded from a
+// "-checksums" file in "filename<TAB>hex" format. The algorithm is inferred
+// from the hex length: 40 characters for SHA1, 64 for SHA256.
+type Manifest map[string]Checksum
+
+func LoadManifest(manifestPath string) (Manifest, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	manifest := Manifest{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed checksums line: %q", line)
+		}
+
+		name, hexDigest := fields[0], fields[1]
+
+		var algorithm string
+		switch len(hexDigest) {
+		case sha1.Size * 2:
+			algorithm = "sha1"
+		case sha256.Size * 2:
+			algorithm = "sha256"
+		default:
+			return nil, fmt.Errorf("unrecognized checksum length for %q", name)
+		}
+
+		manifest[name] = Checksum{Algorithm: algorithm, Hex: hexDigest}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Verify hashes the file at filePath and compares it against the checksum
+// recorded for name, if any. A missing manifest entry is not an error.
+func (manifest Manifest) Verify(filePath string, name string) error {
+	checksum, ok := manifest[name]
+	if !ok {
+		return nil
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var digest hash.Hash
+	switch checksum.Algorithm {
+	case "sha1":
+		digest = sha1.New()
+	case "sha256":
+		digest = sha256.New()
+	default:
+		return fmt.Errorf("unknown checksum algorithm: %s", checksum.Algorithm)
+	}
+
+	if _, err := io.Copy(digest, file); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(digest.Sum(nil))
+	if actual != checksum.Hex {
+		return fmt.Errorf(
+			"checksum mismatch for %s: expected %s, got %s",
+			name, checksum.Hex, actual)
+	}
+
+	return nil
+}
+
+// Attempt is a single file to download, along with the number of times it
+// has already been retried.
+type Attempt struct {
+	Count   int
+	Key     string
+	Name    string
+	Request *http.Request
+}
+
+// Downloader ties together an HTTP client, rate limiter, progress tracker,
+// and optional checksum manifest to drive retrying, resumable downloads.
+type Downloader struct {
+	Client     *httpclient.Client
+	Limiter    *RateLimiter
+	Tracker    *ProgressTracker
+	Manifest   Manifest
+	OutputDir  string
+	RetryCount int
+}
+
+// All runs a pool of workerCount goroutines consuming from a job channel,
+// resubmitting failed downloads up to RetryCount times, and returns once
+// every attempt has either succeeded or been given up on.
+func (downloader *Downloader) All(workerCount int, attempts []Attempt) error {
+	if workerCount < 1 {
+		return fmt.Errorf("worker count must be at least 1, got %d", workerCount)
+	}
+
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	jobs := make(chan Attempt, len(attempts))
+	for _, attempt := range attempts {
+		jobs <- attempt
+	}
+
+	pending := int64(len(attempts))
+	errs := make(chan error, 1)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workerCount; worker += 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for attempt := range jobs {
+				downloader.Limiter.Take()
+
+				log.Printf("downloading %s\n", attempt.Name)
+				err := downloader.Download(attempt)
+				if err == ErrFailed {
+					if attempt.Count < downloader.RetryCount {
+						log.Printf("downloading %s failed; retrying\n", attempt.Name)
+						attempt.Count += 1
+						jobs <- attempt
+						continue
+					}
+
+					log.Printf("downloading %s failed\n", attempt.Name)
+				} else if err != nil {
+					select {
+					case errs <- fmt.Errorf("downloading %s failed: %v", attempt.Name, err):
+					default:
+					}
+				}
+
+				if atomic.AddInt64(&pending, -1) == 0 {
+					close(jobs)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// Download performs a single attempt, resuming a partially-downloaded file
+// via a Range request when possible, and verifying the result against the
+// manifest, if one was configured. It issues both a HEAD probe and the GET
+// itself, so it takes a rate limiter token per request to keep the
+// configured interval meaning one request, not one Download call.
+func (downloader *Downloader) Download(attempt Attempt) error {
+	filePath := path.Join(downloader.OutputDir, attempt.Name)
+
+	downloader.Limiter.Take()
+	total, err := probeContentLength(downloader.Client, attempt.Request)
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	info, err := os.Stat(filePath)
+	if err == nil && total > 0 && info.Size() < total {
+		offset = info.Size()
+		attempt.Request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	request, cancel := downloader.Client.WithDeadline(attempt.Request)
+	defer cancel()
+
+	response, err := downloader.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNoContent {
+		return ErrFailed
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if response.StatusCode == http.StatusPartialContent {
+		flags |= os.O_APPEND
+	} else if response.StatusCode == http.StatusOK {
+		offset = 0
+		flags |= os.O_TRUNC
+	} else {
+		return fmt.Errorf("returned %d status", response.StatusCode)
+	}
+
+	file, err := os.OpenFile(filePath, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	key := attempt.Key
+	if key == "" {
+		key = attempt.Name
+	}
+
+	writer := downloader.Tracker.Start(key, attempt.Name, offset+response.ContentLength)
+	defer downloader.Tracker.Finish(key)
+	writer.Writer = file
+
+	if _, err := io.Copy(writer, response.Body); err != nil {
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if downloader.Manifest != nil {
+		if err := downloader.Manifest.Verify(filePath, attempt.Name); err != nil {
+			log.Printf("%v; deleting and retrying\n", err)
+			os.Remove(filePath)
+			return ErrFailed
+		}
+	}
+
+	return nil
+}
+
+// probeContentLength issues a HEAD request for request's URL to discover the
+// full size of the remote content, returning 0 if it cannot be determined.
+func probeContentLength(client *httpclient.Client, request *http.Request) (int64, error) {
+	headRequest, err := http.NewRequest("HEAD", request.URL.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	headRequest.Header = request.Header.Clone()
+	headRequest.Header.Del("Range")
+
+	headRequest, cancel := client.WithDeadline(headRequest)
+	defer cancel()
+
+	response, err := client.Do(headRequest)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return 0, nil
+	}
+
+	return response.ContentLength, nil
+}
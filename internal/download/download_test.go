@@ -0,0 +1,206 @@
+package download
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+func TestLoadManifestAndVerify(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "checksums.txt")
+	// sha1("hello\n") and sha256("hello\n")
+	content := "part1\tf572d396fae9206628714fb2ce00f72e94f2258f\n" +
+		"part2\t5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filePath := filepath.Join(t.TempDir(), "part1")
+	if err := os.WriteFile(filePath, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manifest.Verify(filePath, "part1"); err != nil {
+		t.Errorf("expected part1 to verify, got %v", err)
+	}
+	if err := manifest.Verify(filePath, "missing"); err != nil {
+		t.Errorf("expected no error for a name absent from the manifest, got %v", err)
+	}
+
+	if err := os.WriteFile(filePath, []byte("goodbye\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.Verify(filePath, "part1"); err == nil {
+		t.Error("expected a checksum mismatch error")
+	}
+}
+
+func TestDownloaderAll_NoAttempts(t *testing.T) {
+	downloader := &Downloader{
+		Client:  mustClient(t),
+		Limiter: NewRateLimiter(time.Millisecond),
+		Tracker: NewProgressTracker(),
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- downloader.All(2, nil) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("All(2, nil) did not return")
+	}
+}
+
+func TestDownloaderAll_InvalidWorkerCount(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloader := &Downloader{
+		Client:    mustClient(t),
+		Limiter:   NewRateLimiter(time.Millisecond),
+		Tracker:   NewProgressTracker(),
+		OutputDir: t.TempDir(),
+	}
+
+	for _, workerCount := range []int{0, -1} {
+		if err := downloader.All(workerCount, []Attempt{{Name: "part.mp3", Request: request}}); err == nil {
+			t.Errorf("All(%d, ...) should have returned an error", workerCount)
+		}
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 0 {
+		t.Errorf("expected no requests to be made, got %d", got)
+	}
+}
+
+func TestDownloaderDownload(t *testing.T) {
+	var requests int32
+	const body = "part contents"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", "13")
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	downloader := &Downloader{
+		Client:    mustClient(t),
+		Limiter:   NewRateLimiter(time.Millisecond),
+		Tracker:   NewProgressTracker(),
+		OutputDir: outputDir,
+	}
+
+	if err := downloader.Download(Attempt{Name: "part.mp3", Request: request}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "part.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != body {
+		t.Errorf("got %q, want %q", data, body)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("got %d requests, want 2 (one HEAD, one GET)", got)
+	}
+}
+
+func TestDownloaderDownload_ResumesPartialFile(t *testing.T) {
+	const full = "0123456789"
+	const already = "01234"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", fmt.Sprint(len(full)))
+			return
+		}
+
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			t.Errorf("expected a Range header on the resumed GET")
+			w.Write([]byte(full))
+			return
+		}
+		if rangeHeader != fmt.Sprintf("bytes=%d-", len(already)) {
+			t.Errorf("got Range header %q, want %q", rangeHeader, fmt.Sprintf("bytes=%d-", len(already)))
+		}
+
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(full[len(already):]))
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "part.mp3"), []byte(already), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloader := &Downloader{
+		Client:    mustClient(t),
+		Limiter:   NewRateLimiter(time.Millisecond),
+		Tracker:   NewProgressTracker(),
+		OutputDir: outputDir,
+	}
+
+	if err := downloader.Download(Attempt{Name: "part.mp3", Request: request}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "part.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != full {
+		t.Errorf("got %q, want %q", data, full)
+	}
+}
+
+func mustClient(t *testing.T) *httpclient.Client {
+	t.Helper()
+	client, err := httpclient.New(httpclient.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// CookieStore is an http.CookieJar that mirrors every cookie it is given
+// into a map keyed by URL, so the jar's contents can be saved to and loaded
+// from a JSON file across runs.
+type CookieStore struct {
+	delegate http.CookieJar
+	path     string
+
+	mu      sync.Mutex
+	cookies map[string][]*http.Cookie
+}
+
+func NewCookieStore(path string, delegate http.CookieJar) (*CookieStore, error) {
+	if delegate == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, err
+		}
+		delegate = jar
+	}
+
+	store := &CookieStore{
+		delegate: delegate,
+		path:     path,
+		cookies:  make(map[string][]*http.Cookie),
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (store *CookieStore) load() error {
+	data, err := os.ReadFile(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &store.cookies); err != nil {
+		return err
+	}
+
+	for rawUrl, cookies := range store.cookies {
+		parsedUrl, err := url.Parse(rawUrl)
+		if err != nil {
+			return err
+		}
+		store.delegate.SetCookies(parsedUrl, cookies)
+	}
+
+	return nil
+}
+
+func (store *CookieStore) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	store.delegate.SetCookies(u, cookies)
+
+	store.mu.Lock()
+	store.cookies[u.String()] = cookies
+	store.mu.Unlock()
+}
+
+func (store *CookieStore) Cookies(u *url.URL) []*http.Cookie {
+	return store.delegate.Cookies(u)
+}
+
+// Save writes the accumulated cookies to the configured path as JSON.
+func (store *CookieStore) Save() error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	data, err := json.MarshalIndent(store.cookies, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(store.path, data, 0644)
+}
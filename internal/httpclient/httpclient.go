@@ -0,0 +1,106 @@
+// Package httpclient builds the shared HTTP client used by the odm and
+// libby flows: proxy-aware, with a per-request deadline that can be reset
+// after construction, and optionally persistent cookies.
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Config configures New. A zero Config builds a client with no deadline, no
+// connect timeout, and an ephemeral in-memory cookie jar.
+type Config struct {
+	// Timeout bounds each request end-to-end. Zero means no deadline.
+	Timeout time.Duration
+	// ConnectTimeout bounds the underlying TCP/TLS dial. Zero means no
+	// dial timeout.
+	ConnectTimeout time.Duration
+	// CookiesPath, if set, persists the cookie jar to this file as JSON
+	// across runs.
+	CookiesPath string
+	// Jar, if set, is used as the underlying cookie jar (for example one
+	// configured with a custom PublicSuffixList). Ignored if CookiesPath
+	// is also set, since the cookie store supplies its own jar.
+	Jar http.CookieJar
+}
+
+// Client wraps an *http.Client with a deadline that can be changed after
+// construction, analogous to net.Conn's SetReadDeadline/SetWriteDeadline,
+// so a worker pool can reset it per job.
+type Client struct {
+	*http.Client
+
+	mu          sync.Mutex
+	timeout     time.Duration
+	cookieStore *CookieStore
+}
+
+func New(config Config) (*Client, error) {
+	jar := config.Jar
+
+	var cookieStore *CookieStore
+	if config.CookiesPath != "" {
+		store, err := NewCookieStore(config.CookiesPath, config.Jar)
+		if err != nil {
+			return nil, err
+		}
+		cookieStore = store
+		jar = store
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: config.ConnectTimeout,
+		}).DialContext,
+	}
+
+	return &Client{
+		Client: &http.Client{
+			Transport: transport,
+			Jar:       jar,
+		},
+		timeout:     config.Timeout,
+		cookieStore: cookieStore,
+	}, nil
+}
+
+// SetTimeout changes the deadline applied by WithDeadline to requests made
+// from this point on.
+func (client *Client) SetTimeout(timeout time.Duration) {
+	client.mu.Lock()
+	client.timeout = timeout
+	client.mu.Unlock()
+}
+
+func (client *Client) Timeout() time.Duration {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.timeout
+}
+
+// WithDeadline returns a copy of request bound by the client's current
+// timeout. The returned cancel function must be called once the request has
+// completed, typically via defer.
+func (client *Client) WithDeadline(request *http.Request) (*http.Request, context.CancelFunc) {
+	timeout := client.Timeout()
+	if timeout <= 0 {
+		return request, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	return request.WithContext(ctx), cancel
+}
+
+// SaveCookies persists the cookie jar to disk, if a CookiesPath was
+// configured. It is a no-op otherwise.
+func (client *Client) SaveCookies() error {
+	if client.cookieStore == nil {
+		return nil
+	}
+	return client.cookieStore.Save()
+}
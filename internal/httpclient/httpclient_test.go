@@ -0,0 +1,91 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithDeadline_NoTimeout(t *testing.T) {
+	client, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := http.NewRequest("GET", "http://example.com", nil)
+	wrapped, cancel := client.WithDeadline(request)
+	defer cancel()
+
+	if wrapped != request {
+		t.Error("expected the original request back when no timeout is configured")
+	}
+	if _, ok := wrapped.Context().Deadline(); ok {
+		t.Error("expected no deadline on the request context")
+	}
+}
+
+func TestWithDeadline_Timeout(t *testing.T) {
+	client, err := New(Config{Timeout: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := http.NewRequest("GET", "http://example.com", nil)
+	wrapped, cancel := client.WithDeadline(request)
+	defer cancel()
+
+	if _, ok := wrapped.Context().Deadline(); !ok {
+		t.Error("expected a deadline on the request context")
+	}
+}
+
+func TestSetTimeout(t *testing.T) {
+	client, err := New(Config{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.SetTimeout(0)
+
+	request, _ := http.NewRequest("GET", "http://example.com", nil)
+	wrapped, cancel := client.WithDeadline(request)
+	defer cancel()
+
+	if wrapped != request {
+		t.Error("expected SetTimeout(0) to disable the deadline")
+	}
+}
+
+func TestCookiePersistence(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc123"})
+	}))
+	defer server.Close()
+
+	cookiesPath := filepath.Join(t.TempDir(), "cookies.json")
+
+	client, err := New(Config{CookiesPath: cookiesPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Get(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SaveCookies(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := New(Config{CookiesPath: cookiesPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := http.NewRequest("GET", server.URL, nil)
+	cookies := reloaded.Jar.Cookies(request.URL)
+	if len(cookies) != 1 || cookies[0].Value != "abc123" {
+		t.Fatalf("expected the session cookie to survive a reload, got %v", cookies)
+	}
+}
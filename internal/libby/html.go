@@ -0,0 +1,65 @@
+package libby
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+var htmlPath = []htmlNode{{
+	Tag: "html",
+}, {
+	Tag: "body",
+}, {
+	Tag: "div",
+	Id:  "BIFOCAL-runtime",
+}, {
+	Tag: "script",
+	Id:  "BIFOCAL-data",
+}}
+
+// extractData walks the page looking for the <script id="BIFOCAL-data">
+// element and returns the JSON object assigned to window.bData within it.
+func extractData(body io.Reader) ([]byte, error) {
+	document, err := html.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+pathLoop:
+	for _, selector := range htmlPath {
+		for node := current.FirstChild; node != nil; node = node.NextSibling {
+			if node.Type != html.ElementNode {
+				continue
+			}
+			if node.Data != selector.Tag {
+				continue
+			}
+			if selector.Id == "" {
+				current = node
+				continue pathLoop
+			}
+			for _, attribute := range node.Attr {
+				if attribute.Key != "id" {
+					continue
+				}
+				if attribute.Val != selector.Id {
+					continue
+				}
+				current = node
+				continue pathLoop
+			}
+		}
+		return nil, fmt.Errorf("unable to find element <%s>", selector.Tag)
+	}
+
+	dataBytes := []byte(html.UnescapeString(current.FirstChild.Data))
+	match := dataRegxp.FindSubmatch(dataBytes)
+	if match == nil {
+		return nil, fmt.Errorf("unable to find window.bData in script contents")
+	}
+
+	return match[1], nil
+}
@@ -0,0 +1,122 @@
+// Package libby scrapes the Libby/OverDrive "read online" page for its
+// embedded spine model, the ordered list of parts making up the title.
+package libby
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"path"
+	"regexp"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+var dataRegxp = regexp.MustCompile("window.bData = (?P<Data>{.*})")
+
+// PublicSuffixList treats every domain as its own public suffix, so the
+// cookie jar scopes cookies to the exact host Libby set them on.
+type PublicSuffixList struct{}
+
+func (PublicSuffixList) PublicSuffix(domain string) string {
+	return domain
+}
+
+func (PublicSuffixList) String() string {
+	return "PublicSuffixList"
+}
+
+type htmlNode struct {
+	Tag string
+	Id  string
+}
+
+type Data struct {
+	Spine []Spine `json:"spine"`
+}
+
+type Spine struct {
+	Path         string `json:"path"`
+	OriginalPath string `json:"-odread-original-path"`
+}
+
+// NewClient builds an httpclient.Client with a cookie jar suitable for the
+// Libby flow, where the initial page sets session cookies the spine
+// requests depend on. config.Jar is ignored; the jar is always seeded with
+// PublicSuffixList.
+func NewClient(config httpclient.Config) (*httpclient.Client, error) {
+	jar, err := cookiejar.New(&cookiejar.Options{
+		PublicSuffixList: PublicSuffixList{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config.Jar = jar
+	return httpclient.New(config)
+}
+
+// Fetch retrieves pageUrl and extracts its embedded spine model, returning
+// the raw JSON (for archival alongside the downloaded parts), the parsed
+// Data, and the response that served it (so callers can resolve spine paths
+// against its final URL).
+func Fetch(client *httpclient.Client, pageUrl string) ([]byte, Data, *http.Response, error) {
+	request, err := http.NewRequest("GET", pageUrl, nil)
+	if err != nil {
+		return nil, Data{}, nil, err
+	}
+	request.Header.Set("User-Agent", "nobody")
+
+	request, cancel := client.WithDeadline(request)
+	defer cancel()
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, Data{}, nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		responseBody, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, Data{}, nil, err
+		}
+		return nil, Data{}, nil, fmt.Errorf("url returned a %d status: %s",
+			response.StatusCode, responseBody)
+	}
+
+	dataBytes, err := extractData(response.Body)
+	if err != nil {
+		return nil, Data{}, nil, err
+	}
+
+	data := Data{}
+	if err := json.Unmarshal(dataBytes, &data); err != nil {
+		return nil, Data{}, nil, err
+	}
+
+	return dataBytes, data, response, nil
+}
+
+// SaveData writes the raw spine JSON fetched by Fetch to metadata.json in
+// outputDirectory.
+func SaveData(dataBytes []byte, outputDirectory string) error {
+	filePath := path.Join(outputDirectory, "metadata.json")
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(dataBytes)
+	return err
+}
+
+// PartUrl resolves a spine entry's path against the host that served the
+// page, as required by the download request's Referer.
+func PartUrl(pageResponse *http.Response, spine Spine) string {
+	return fmt.Sprintf("%s://%s/%s",
+		pageResponse.Request.URL.Scheme, pageResponse.Request.URL.Host, spine.Path)
+}
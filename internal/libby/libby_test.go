@@ -0,0 +1,58 @@
+package libby
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+const fixtureHTML = `<html><body><div id="BIFOCAL-runtime"><script id="BIFOCAL-data">
+window.bData = {"spine":[{"path":"part1.mp3","-odread-original-path":"Part 1.mp3"}]};
+</script></div></body></html>`
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fixtureHTML))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(httpclient.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataBytes, data, response, err := Fetch(client, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dataBytes) == 0 {
+		t.Error("expected non-empty raw data bytes")
+	}
+	if len(data.Spine) != 1 || data.Spine[0].OriginalPath != "Part 1.mp3" {
+		t.Errorf("got spine %+v, want one entry named %q", data.Spine, "Part 1.mp3")
+	}
+
+	partUrl := PartUrl(response, data.Spine[0])
+	if want := server.URL + "/part1.mp3"; partUrl != want {
+		t.Errorf("got part url %q, want %q", partUrl, want)
+	}
+}
+
+func TestFetch_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(httpclient.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := Fetch(client, server.URL); err == nil {
+		t.Fatal("expected an error for a non-200 response")
+	}
+}
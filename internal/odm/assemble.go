@@ -0,0 +1,310 @@
+package odm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+// Markers is the "OverDrive MediaMarkers" ID3 comment payload embedded in
+// each downloaded part, giving chapter names and their offsets within that
+// part.
+type Markers struct {
+	Marker []Marker `xml:"Marker"`
+}
+
+type Marker struct {
+	Name string `xml:"Name"`
+	Time string `xml:"Time"`
+}
+
+// Chapter is a single chapter in the assembled audiobook, with Offset
+// measured from the start of the whole book.
+type Chapter struct {
+	Name   string
+	Offset time.Duration
+}
+
+func parseMarkers(raw string) ([]Marker, error) {
+	markers := Markers{}
+	if err := xml.Unmarshal([]byte(raw), &markers); err != nil {
+		return nil, err
+	}
+	return markers.Marker, nil
+}
+
+// parseMarkerTime parses a MediaMarker timestamp, either "mm:ss.mmm" or
+// "hh:mm:ss.mmm".
+func parseMarkerTime(value string) (time.Duration, error) {
+	fields := strings.Split(value, ":")
+
+	var hours, minutes int
+	var seconds float64
+	var err error
+
+	switch len(fields) {
+	case 2:
+		minutes, err = strconv.Atoi(fields[0])
+		if err != nil {
+			return 0, err
+		}
+		seconds, err = strconv.ParseFloat(fields[1], 64)
+	case 3:
+		hours, err = strconv.Atoi(fields[0])
+		if err != nil {
+			return 0, err
+		}
+		minutes, err = strconv.Atoi(fields[1])
+		if err != nil {
+			return 0, err
+		}
+		seconds, err = strconv.ParseFloat(fields[2], 64)
+	default:
+		return 0, fmt.Errorf("unrecognized marker time: %q", value)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), nil
+}
+
+// partChapters reads the MediaMarkers and duration out of a downloaded
+// part's ID3 tag, returning chapters with Offset relative to the start of
+// that part.
+func partChapters(partPath string) ([]Chapter, time.Duration, error) {
+	file, err := os.Open(partPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	frames, _, err := readID3v2Tag(file)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var duration time.Duration
+	if millis, ok := trackLengthMillis(frames); ok {
+		duration = time.Duration(millis) * time.Millisecond
+	}
+
+	comment, ok := mediaMarkersComment(frames)
+	if !ok {
+		return nil, duration, nil
+	}
+
+	markers, err := parseMarkers(comment)
+	if err != nil {
+		return nil, duration, err
+	}
+
+	chapters := make([]Chapter, len(markers))
+	for index, marker := range markers {
+		offset, err := parseMarkerTime(marker.Time)
+		if err != nil {
+			return nil, duration, err
+		}
+
+		chapters[index] = Chapter{Name: marker.Name, Offset: offset}
+	}
+
+	return chapters, duration, nil
+}
+
+// FetchCover downloads the cover art referenced by a <CoverUrl> element and
+// saves it alongside the parts, returning the path it was written to. It
+// uses client so the configured timeout/proxy settings also bound this
+// request.
+func FetchCover(client *httpclient.Client, coverUrl string, outputDirectory string) (string, error) {
+	if coverUrl == "" {
+		return "", nil
+	}
+
+	request, err := http.NewRequest("GET", coverUrl, nil)
+	if err != nil {
+		return "", err
+	}
+
+	request, cancel := client.WithDeadline(request)
+	defer cancel()
+
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching cover returned a %d status", response.StatusCode)
+	}
+
+	coverPath := path.Join(outputDirectory, "cover.jpg")
+	file, err := os.Create(coverPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, response.Body); err != nil {
+		return "", err
+	}
+
+	return coverPath, nil
+}
+
+// TagParts writes TIT2/TPE1/TALB/TRCK/APIC ID3v2 frames onto each downloaded
+// part.
+func TagParts(
+	outputDirectory string, parts []Part, metadata MediaMetadata, coverPath string,
+) error {
+	var cover []byte
+	if coverPath != "" {
+		data, err := os.ReadFile(coverPath)
+		if err != nil {
+			return err
+		}
+		cover = data
+	}
+
+	for _, part := range parts {
+		partPath := path.Join(outputDirectory, fmt.Sprintf("%s.mp3", part.Name))
+
+		frames := []id3Frame{
+			textFrame("TIT2", metadata.Title),
+			textFrame("TPE1", metadata.Author()),
+			textFrame("TALB", metadata.Title),
+			trackFrame(part.Number, len(parts)),
+		}
+		if narrator := metadata.Narrator(); narrator != "" {
+			// TCOM (composer) is the frame most audiobook players surface
+			// as "narrated by", so repurpose it rather than add a TXXX.
+			frames = append(frames, textFrame("TCOM", narrator))
+		}
+		if cover != nil {
+			frames = append(frames, pictureFrame("image/jpeg", cover))
+		}
+
+		if err := writeID3v2Tag(partPath, frames); err != nil {
+			return fmt.Errorf("tagging part %d: %w", part.Number, err)
+		}
+	}
+
+	return nil
+}
+
+// AssembleM4B concatenates the downloaded parts into a single chaptered M4B
+// via an ffmpeg subprocess.
+func AssembleM4B(
+	outputDirectory string, parts []Part, metadata MediaMetadata, coverPath string,
+) error {
+	var chapters []Chapter
+	var offset time.Duration
+
+	listPath := path.Join(outputDirectory, "parts.txt")
+	listFile, err := os.Create(listPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(listPath)
+
+	for _, part := range parts {
+		partPath := path.Join(outputDirectory, fmt.Sprintf("%s.mp3", part.Name))
+
+		partChapterList, duration, err := partChapters(partPath)
+		if err != nil {
+			listFile.Close()
+			return fmt.Errorf("reading chapters for part %d: %w", part.Number, err)
+		}
+
+		for _, chapter := range partChapterList {
+			chapters = append(chapters, Chapter{
+				Name:   chapter.Name,
+				Offset: offset + chapter.Offset,
+			})
+		}
+		offset += duration
+
+		fmt.Fprintf(listFile, "file '%s'\n", partPath)
+	}
+	if err := listFile.Close(); err != nil {
+		return err
+	}
+
+	chaptersPath := path.Join(outputDirectory, "chapters.txt")
+	if err := writeChaptersFile(chaptersPath, metadata, chapters, offset); err != nil {
+		return err
+	}
+	defer os.Remove(chaptersPath)
+
+	outputPath := path.Join(outputDirectory, fmt.Sprintf("%s.m4b", metadata.Title))
+
+	args := []string{
+		"-y",
+		"-f", "concat", "-safe", "0", "-i", listPath,
+		"-i", chaptersPath,
+		"-map_metadata", "1",
+	}
+	if coverPath != "" {
+		args = append(args,
+			"-i", coverPath,
+			"-map", "0:a", "-map", "2:v",
+			"-disposition:v:0", "attached_pic")
+	} else {
+		args = append(args, "-map", "0:a")
+	}
+	args = append(args, "-c:a", "aac", "-c:v", "copy", outputPath)
+
+	command := exec.Command("ffmpeg", args...)
+	output, err := command.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, output)
+	}
+
+	return nil
+}
+
+// writeChaptersFile writes an ffmpeg metadata file describing chapters, in
+// the format expected by "-i chapters.txt -map_metadata 1".
+func writeChaptersFile(
+	chaptersPath string, metadata MediaMetadata, chapters []Chapter, total time.Duration,
+) error {
+	file, err := os.Create(chaptersPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintln(file, ";FFMETADATA1")
+	fmt.Fprintf(file, "title=%s\n", metadata.Title)
+	fmt.Fprintf(file, "artist=%s\n", metadata.Author())
+	if narrator := metadata.Narrator(); narrator != "" {
+		fmt.Fprintf(file, "composer=%s\n", narrator)
+	}
+
+	for index, chapter := range chapters {
+		end := total
+		if index+1 < len(chapters) {
+			end = chapters[index+1].Offset
+		}
+
+		fmt.Fprintln(file, "[CHAPTER]")
+		fmt.Fprintln(file, "TIMEBASE=1/1000")
+		fmt.Fprintf(file, "START=%d\n", chapter.Offset.Milliseconds())
+		fmt.Fprintf(file, "END=%d\n", end.Milliseconds())
+		fmt.Fprintf(file, "title=%s\n", chapter.Name)
+	}
+
+	return nil
+}
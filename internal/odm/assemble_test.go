@@ -0,0 +1,146 @@
+package odm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+func TestFetchCover(t *testing.T) {
+	const coverBytes = "jpeg bytes"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(coverBytes))
+	}))
+	defer server.Close()
+
+	client, err := httpclient.New(httpclient.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	coverPath, err := FetchCover(client, server.URL, outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(coverPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != coverBytes {
+		t.Errorf("got %q, want %q", data, coverBytes)
+	}
+}
+
+func TestFetchCover_NoUrl(t *testing.T) {
+	client, err := httpclient.New(httpclient.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coverPath, err := FetchCover(client, "", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coverPath != "" {
+		t.Errorf("got %q, want empty path for an empty cover url", coverPath)
+	}
+}
+
+func TestPartChapters(t *testing.T) {
+	partPath := filepath.Join(t.TempDir(), "part.mp3")
+	if err := os.WriteFile(partPath, []byte("mp3 audio bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	markersXml := "<Markers>" +
+		"<Marker><Name>Chapter 1</Name><Time>0:00.000</Time></Marker>" +
+		"<Marker><Name>Chapter 2</Name><Time>1:30.000</Time></Marker>" +
+		"</Markers>"
+
+	frames := []id3Frame{
+		{Id: "COMM", Body: buildCommentFrame(0, "eng", "OverDrive MediaMarkers", markersXml)},
+		textFrame("TLEN", "180000"),
+	}
+	if err := writeID3v2Tag(partPath, frames); err != nil {
+		t.Fatal(err)
+	}
+
+	chapters, duration, err := partChapters(partPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if duration.Milliseconds() != 180000 {
+		t.Errorf("got duration %v, want 180s", duration)
+	}
+	if len(chapters) != 2 {
+		t.Fatalf("got %d chapters, want 2", len(chapters))
+	}
+	if chapters[0].Name != "Chapter 1" || chapters[0].Offset != 0 {
+		t.Errorf("chapter 0: got %+v", chapters[0])
+	}
+	if chapters[1].Name != "Chapter 2" || chapters[1].Offset.Seconds() != 90 {
+		t.Errorf("chapter 1: got %+v", chapters[1])
+	}
+}
+
+func TestTagParts(t *testing.T) {
+	outputDir := t.TempDir()
+	parts := []Part{
+		{Filename: "part1.mp3", Name: "part1", Number: 1},
+		{Filename: "part2.mp3", Name: "part2", Number: 2},
+	}
+	for _, part := range parts {
+		partPath := filepath.Join(outputDir, part.Name+".mp3")
+		if err := os.WriteFile(partPath, []byte("mp3 audio bytes"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	metadata := MediaMetadata{
+		Title: "Sample Book",
+		Creators: []Creator{
+			{Role: "Author", Name: "Jane Author"},
+			{Role: "Narrator", Name: "Nora Narrator"},
+		},
+	}
+
+	if err := TagParts(outputDir, parts, metadata, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filepath.Join(outputDir, "part1.mp3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	frames, _, err := readID3v2Tag(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byId := make(map[string]string)
+	for _, frame := range frames {
+		if len(frame.Body) > 0 {
+			byId[frame.Id] = string(frame.Body[1:])
+		}
+	}
+
+	if byId["TIT2"] != metadata.Title {
+		t.Errorf("got TIT2 %q, want %q", byId["TIT2"], metadata.Title)
+	}
+	if byId["TPE1"] != "Jane Author" {
+		t.Errorf("got TPE1 %q, want %q", byId["TPE1"], "Jane Author")
+	}
+	if byId["TCOM"] != "Nora Narrator" {
+		t.Errorf("got TCOM %q, want %q", byId["TCOM"], "Nora Narrator")
+	}
+}
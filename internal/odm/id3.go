@@ -0,0 +1,268 @@
+package odm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// id3Frame is a single ID3v2.3 frame: a 4-character identifier and its raw
+// body (the encoding byte, if any, is included in Body).
+type id3Frame struct {
+	Id   string
+	Body []byte
+}
+
+// readID3v2Tag reads the ID3v2.3/2.4 header and frames at the start of
+// reader, if any, returning the frames and the total on-disk size of the
+// tag (header plus frames) so it can be stripped before rewriting.
+func readID3v2Tag(reader io.Reader) ([]id3Frame, int64, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	if string(header[0:3]) != "ID3" {
+		return nil, 0, nil
+	}
+
+	majorVersion := header[3]
+	if majorVersion != 3 && majorVersion != 4 {
+		return nil, 0, fmt.Errorf("unsupported id3v2 version: 2.%d", majorVersion)
+	}
+
+	size := decodeSynchsafe(header[6:10])
+	body := make([]byte, size)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, 0, err
+	}
+
+	var frames []id3Frame
+	offset := 0
+	for offset+10 <= len(body) {
+		id := string(body[offset : offset+4])
+		if id == "\x00\x00\x00\x00" {
+			break
+		}
+
+		var frameSize int
+		if majorVersion == 4 {
+			frameSize = int(decodeSynchsafe(body[offset+4 : offset+8]))
+		} else {
+			frameSize = int(binary.BigEndian.Uint32(body[offset+4 : offset+8]))
+		}
+		frameStart := offset + 10
+		frameEnd := frameStart + frameSize
+		if frameEnd > len(body) {
+			break
+		}
+
+		frames = append(frames, id3Frame{
+			Id:   id,
+			Body: body[frameStart:frameEnd],
+		})
+
+		offset = frameEnd
+	}
+
+	return frames, 10 + int64(size), nil
+}
+
+func decodeSynchsafe(data []byte) uint32 {
+	return uint32(data[0])<<21 | uint32(data[1])<<14 | uint32(data[2])<<7 | uint32(data[3])
+}
+
+func encodeSynchsafe(size uint32) []byte {
+	return []byte{
+		byte((size >> 21) & 0x7F),
+		byte((size >> 14) & 0x7F),
+		byte((size >> 7) & 0x7F),
+		byte(size & 0x7F),
+	}
+}
+
+// mediaMarkersComment returns the text of the "OverDrive MediaMarkers" COMM
+// frame among frames, if present.
+func mediaMarkersComment(frames []id3Frame) (string, bool) {
+	for _, frame := range frames {
+		if frame.Id != "COMM" || len(frame.Body) < 5 {
+			continue
+		}
+
+		// encoding(1) + language(3) + short description, terminated.
+		encoding := frame.Body[0]
+		description, text, ok := splitId3Text(encoding, frame.Body[4:])
+		if !ok || description != "OverDrive MediaMarkers" {
+			continue
+		}
+
+		return text, true
+	}
+
+	return "", false
+}
+
+// splitId3Text splits an ID3v2 "short description\x00actual text" pair,
+// honoring the frame's text encoding byte (0=ISO-8859-1, 1=UTF-16 with BOM,
+// 2=UTF-16BE, 3=UTF-8): encodings 1 and 2 use a two-byte NUL terminator and
+// UTF-16 code units instead of the single NUL byte used by 0 and 3.
+func splitId3Text(encoding byte, data []byte) (description string, text string, ok bool) {
+	if encoding == 1 || encoding == 2 {
+		index := utf16NullIndex(data)
+		if index < 0 {
+			return "", "", false
+		}
+		return decodeUTF16(data[:index], encoding), decodeUTF16(data[index+2:], encoding), true
+	}
+
+	index := bytes.IndexByte(data, 0)
+	if index < 0 {
+		return "", "", false
+	}
+	return string(data[:index]), string(data[index+1:]), true
+}
+
+// utf16NullIndex returns the byte offset of the first two-byte NUL code
+// unit in data, aligned to an even offset, or -1 if none is found.
+func utf16NullIndex(data []byte) int {
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 && data[i+1] == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeUTF16 decodes data as UTF-16, honoring a leading byte-order mark for
+// encoding 1 (UTF-16 with BOM) and assuming big-endian for encoding 2
+// (UTF-16BE without BOM).
+func decodeUTF16(data []byte, encoding byte) string {
+	order := binary.ByteOrder(binary.BigEndian)
+	if encoding == 1 && len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE {
+		order = binary.LittleEndian
+		data = data[2:]
+	} else if encoding == 1 && len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF {
+		data = data[2:]
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:i+2]))
+	}
+
+	return string(utf16.Decode(units))
+}
+
+// trackLengthMillis returns the duration in milliseconds recorded in a TLEN
+// frame, if present.
+func trackLengthMillis(frames []id3Frame) (int64, bool) {
+	for _, frame := range frames {
+		if frame.Id != "TLEN" || len(frame.Body) < 2 {
+			continue
+		}
+
+		text := strings.TrimRight(string(frame.Body[1:]), "\x00")
+		millis, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		return millis, true
+	}
+
+	return 0, false
+}
+
+func textFrame(id string, value string) id3Frame {
+	body := append([]byte{0}, []byte(value)...)
+	return id3Frame{Id: id, Body: body}
+}
+
+func trackFrame(number uint, total int) id3Frame {
+	return textFrame("TRCK", fmt.Sprintf("%d/%d", number, total))
+}
+
+func pictureFrame(mimeType string, data []byte) id3Frame {
+	body := []byte{0}
+	body = append(body, []byte(mimeType)...)
+	body = append(body, 0)
+	body = append(body, 3) // picture type 3 = front cover
+	body = append(body, 0) // empty description
+	body = append(body, data...)
+	return id3Frame{Id: "APIC", Body: body}
+}
+
+// writeID3v2Tag strips any existing ID3v2 tag from path and prepends a fresh
+// tag built from frames.
+func writeID3v2Tag(path string, frames []id3Frame) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	_, tagSize, err := readID3v2Tag(source)
+	if err != nil {
+		return err
+	}
+
+	if _, err := source.Seek(tagSize, io.SeekStart); err != nil {
+		return err
+	}
+
+	tempPath := path + ".tmp"
+	dest, err := os.Create(tempPath)
+	if err != nil {
+		return err
+	}
+
+	if err := writeFrames(dest, frames); err != nil {
+		dest.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if _, err := io.Copy(dest, source); err != nil {
+		dest.Close()
+		os.Remove(tempPath)
+		return err
+	}
+
+	if err := dest.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	return os.Rename(tempPath, path)
+}
+
+func writeFrames(writer io.Writer, frames []id3Frame) error {
+	var body bytes.Buffer
+	for _, frame := range frames {
+		body.WriteString(frame.Id)
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(frame.Body)))
+		body.Write(size[:])
+		body.Write([]byte{0, 0}) // flags
+		body.Write(frame.Body)
+	}
+
+	header := []byte("ID3")
+	header = append(header, 3, 0) // version 2.3.0
+	header = append(header, 0)    // flags
+	header = append(header, encodeSynchsafe(uint32(body.Len()))...)
+
+	if _, err := writer.Write(header); err != nil {
+		return err
+	}
+	_, err := writer.Write(body.Bytes())
+	return err
+}
@@ -0,0 +1,192 @@
+package odm
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteAndReadID3v2Tag(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "part.mp3")
+	if err := os.WriteFile(filePath, []byte("mp3 audio bytes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	frames := []id3Frame{
+		textFrame("TIT2", "Chapter One"),
+		textFrame("TPE1", "Jane Author"),
+		trackFrame(1, 3),
+	}
+	if err := writeID3v2Tag(filePath, frames); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	readFrames, tagSize, err := readID3v2Tag(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tagSize <= 0 {
+		t.Fatalf("expected a positive tag size, got %d", tagSize)
+	}
+	if len(readFrames) != len(frames) {
+		t.Fatalf("got %d frames, want %d", len(readFrames), len(frames))
+	}
+	for i, frame := range frames {
+		if readFrames[i].Id != frame.Id || !bytes.Equal(readFrames[i].Body, frame.Body) {
+			t.Errorf("frame %d: got %+v, want %+v", i, readFrames[i], frame)
+		}
+	}
+
+	rest, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasSuffix(rest, []byte("mp3 audio bytes")) {
+		t.Error("expected the original audio bytes to follow the rewritten tag")
+	}
+}
+
+func TestReadID3v2Tag_UnsupportedVersion(t *testing.T) {
+	header := []byte("ID3")
+	header = append(header, 2, 0) // major version 2, unsupported
+	header = append(header, 0)
+	header = append(header, encodeSynchsafe(0)...)
+
+	if _, _, err := readID3v2Tag(bytes.NewReader(header)); err == nil {
+		t.Fatal("expected an error for an unsupported id3v2 version")
+	}
+}
+
+func TestReadID3v2Tag_NoTag(t *testing.T) {
+	frames, tagSize, err := readID3v2Tag(bytes.NewReader([]byte("not an id3 tag")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if frames != nil || tagSize != 0 {
+		t.Errorf("got (%v, %d), want (nil, 0)", frames, tagSize)
+	}
+}
+
+func TestMediaMarkersComment_Latin1(t *testing.T) {
+	body := buildCommentFrame(0, "eng", "OverDrive MediaMarkers", "<Markers><Marker><Name>Ch 1</Name><Time>0:00.000</Time></Marker></Markers>")
+	frames := []id3Frame{{Id: "COMM", Body: body}}
+
+	comment, ok := mediaMarkersComment(frames)
+	if !ok {
+		t.Fatal("expected to find the MediaMarkers comment")
+	}
+	if want := "<Markers><Marker><Name>Ch 1</Name><Time>0:00.000</Time></Marker></Markers>"; comment != want {
+		t.Errorf("got %q, want %q", comment, want)
+	}
+}
+
+func TestMediaMarkersComment_UTF16(t *testing.T) {
+	body := buildUTF16CommentFrame(t, "OverDrive MediaMarkers", "<Markers><Marker><Name>Ch 1</Name><Time>0:00.000</Time></Marker></Markers>")
+	frames := []id3Frame{{Id: "COMM", Body: body}}
+
+	comment, ok := mediaMarkersComment(frames)
+	if !ok {
+		t.Fatal("expected to find the MediaMarkers comment in a UTF-16 frame")
+	}
+	if want := "<Markers><Marker><Name>Ch 1</Name><Time>0:00.000</Time></Marker></Markers>"; comment != want {
+		t.Errorf("got %q, want %q", comment, want)
+	}
+}
+
+func TestMediaMarkersComment_WrongDescription(t *testing.T) {
+	body := buildCommentFrame(0, "eng", "Some Other Comment", "irrelevant text")
+	frames := []id3Frame{{Id: "COMM", Body: body}}
+
+	if _, ok := mediaMarkersComment(frames); ok {
+		t.Fatal("expected no match for a COMM frame with a different description")
+	}
+}
+
+func TestTrackLengthMillis(t *testing.T) {
+	frames := []id3Frame{textFrame("TLEN", "123456")}
+
+	millis, ok := trackLengthMillis(frames)
+	if !ok {
+		t.Fatal("expected to find a TLEN frame")
+	}
+	if millis != 123456 {
+		t.Errorf("got %d, want 123456", millis)
+	}
+}
+
+func TestParseMarkerTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"0:00.000", 0},
+		{"1:02.500", time.Minute + 2*time.Second + 500*time.Millisecond},
+		{"1:01:02.500", time.Hour + time.Minute + 2*time.Second + 500*time.Millisecond},
+	}
+
+	for _, test := range tests {
+		got, err := parseMarkerTime(test.value)
+		if err != nil {
+			t.Errorf("parseMarkerTime(%q): %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseMarkerTime(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
+
+func TestParseMarkerTime_Invalid(t *testing.T) {
+	if _, err := parseMarkerTime("not-a-time"); err == nil {
+		t.Fatal("expected an error for an unrecognized marker time")
+	}
+}
+
+func TestSynchsafeRoundTrip(t *testing.T) {
+	for _, size := range []uint32{0, 1, 127, 128, 16384, 2097151} {
+		if got := decodeSynchsafe(encodeSynchsafe(size)); got != size {
+			t.Errorf("round-tripping %d: got %d", size, got)
+		}
+	}
+}
+
+// buildCommentFrame builds a COMM frame body (encoding + language + NUL
+// terminated description + text) for the given single-byte encoding.
+func buildCommentFrame(encoding byte, language string, description string, text string) []byte {
+	body := []byte{encoding}
+	body = append(body, []byte(language)...)
+	body = append(body, []byte(description)...)
+	body = append(body, 0)
+	body = append(body, []byte(text)...)
+	return body
+}
+
+// buildUTF16CommentFrame builds a COMM frame body with encoding 1 (UTF-16
+// with a little-endian BOM on both the description and the text), matching
+// how a server might emit a non-Latin1-safe MediaMarkers comment.
+func buildUTF16CommentFrame(t *testing.T, description string, text string) []byte {
+	t.Helper()
+
+	body := []byte{1}
+	body = append(body, []byte("eng")...)
+	body = append(body, encodeUTF16WithBOM(description)...)
+	body = append(body, 0, 0)
+	body = append(body, encodeUTF16WithBOM(text)...)
+	return body
+}
+
+func encodeUTF16WithBOM(value string) []byte {
+	data := []byte{0xFF, 0xFE}
+	for _, r := range value {
+		data = append(data, byte(r), 0)
+	}
+	return data
+}
@@ -0,0 +1,53 @@
+package odm
+
+import "encoding/xml"
+
+// MediaMetadata is the subset of the OverDrive <Metadata> CDATA block (a
+// nested, separately-namespaced XML document) needed to tag the assembled
+// audiobook.
+type MediaMetadata struct {
+	Title    string    `xml:"Title"`
+	SubTitle string    `xml:"SubTitle"`
+	Series   string    `xml:"Series"`
+	Creators []Creator `xml:"Creators>Creator"`
+	CoverUrl string    `xml:"CoverUrl"`
+}
+
+type Creator struct {
+	Role string `xml:"role,attr"`
+	Name string `xml:",chardata"`
+}
+
+// Author returns the first creator tagged with the "Author" role, or the
+// first creator if none is explicitly tagged.
+func (metadata MediaMetadata) Author() string {
+	for _, creator := range metadata.Creators {
+		if creator.Role == "Author" {
+			return creator.Name
+		}
+	}
+
+	if len(metadata.Creators) > 0 {
+		return metadata.Creators[0].Name
+	}
+
+	return ""
+}
+
+// Narrator returns the first creator tagged with the "Narrator" role.
+func (metadata MediaMetadata) Narrator() string {
+	for _, creator := range metadata.Creators {
+		if creator.Role == "Narrator" {
+			return creator.Name
+		}
+	}
+
+	return ""
+}
+
+// ParseMetadata parses the raw <Metadata> CDATA block of a Media.
+func ParseMetadata(raw string) (MediaMetadata, error) {
+	metadata := MediaMetadata{}
+	err := xml.Unmarshal([]byte(raw), &metadata)
+	return metadata, err
+}
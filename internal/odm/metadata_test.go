@@ -0,0 +1,38 @@
+package odm
+
+import "testing"
+
+const sampleMetadata = `<Metadata>
+	<Title>Sample Book</Title>
+	<Creators>
+		<Creator role="Author">Jane Author</Creator>
+		<Creator role="Narrator">Nora Narrator</Creator>
+	</Creators>
+	<CoverUrl>https://example.com/cover.jpg</CoverUrl>
+</Metadata>`
+
+func TestParseMetadata(t *testing.T) {
+	metadata, err := ParseMetadata(sampleMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata.Title != "Sample Book" {
+		t.Errorf("got title %q, want %q", metadata.Title, "Sample Book")
+	}
+	if author := metadata.Author(); author != "Jane Author" {
+		t.Errorf("got author %q, want %q", author, "Jane Author")
+	}
+	if narrator := metadata.Narrator(); narrator != "Nora Narrator" {
+		t.Errorf("got narrator %q, want %q", narrator, "Nora Narrator")
+	}
+}
+
+func TestMediaMetadata_NoNarrator(t *testing.T) {
+	metadata := MediaMetadata{
+		Creators: []Creator{{Role: "Author", Name: "Jane Author"}},
+	}
+	if narrator := metadata.Narrator(); narrator != "" {
+		t.Errorf("got narrator %q, want empty string", narrator)
+	}
+}
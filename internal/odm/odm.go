@@ -0,0 +1,106 @@
+// Package odm parses OverDrive Media (.odm) files and implements the
+// client/hash handshake needed to acquire a license, download parts, and
+// release a loan early.
+package odm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+const ClientId = "00000000-0000-0000-0000-000000000000"
+const HashSecret = "ELOSNOC*AIDEM*EVIRDREVO"
+const OMC = "1.2.0"
+const OS = "10.14.2"
+const UserAgent = "OverDrive Media Console"
+
+type Media struct {
+	AcquisitionUrl Url      `xml:"License>AcquisitionUrl"`
+	EarlyReturnUrl Url      `xml:"License>EarlyReturnURL"`
+	ContentId      string   `xml:"id,attr"`
+	Formats        []Format `xml:"Formats>Format"`
+	Metadata       string   `xml:"Metadata"`
+}
+
+type Format struct {
+	Parts     Parts      `xml:"Parts"`
+	Protocols []Protocol `xml:"Protocols>Protocol"`
+}
+
+type Protocol struct {
+	Method  string `xml:"method,attr"`
+	BaseUrl string `xml:"baseurl,attr"`
+}
+
+type Parts struct {
+	Count int    `xml:"count,attr"`
+	Part  []Part `xml:"Part"`
+}
+
+type Part struct {
+	Filename string `xml:"filename,attr"`
+	Name     string `xml:"name,attr"`
+	Number   uint   `xml:"number,attr"`
+}
+
+type Url struct {
+	Value *url.URL
+}
+
+func (u *Url) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var value string
+	err := d.DecodeElement(&value, &start)
+	if err != nil {
+		return err
+	}
+
+	u.Value, err = url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Load reads and parses the .odm file at path.
+func Load(path string) (Media, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Media{}, err
+	}
+	defer file.Close()
+
+	media := Media{}
+	if err := xml.NewDecoder(file).Decode(&media); err != nil {
+		return Media{}, err
+	}
+
+	return media, nil
+}
+
+// Validate checks that media has exactly the one format/protocol shape this
+// package knows how to download.
+func (media Media) Validate() error {
+	if len(media.Formats) != 1 {
+		return fmt.Errorf("expected 1 format, got %d", len(media.Formats))
+	}
+
+	if len(media.Formats[0].Parts.Part) != media.Formats[0].Parts.Count {
+		return fmt.Errorf("expected %d format, got %d",
+			media.Formats[0].Parts.Count, len(media.Formats))
+	}
+
+	if len(media.Formats[0].Protocols) != 1 {
+		return fmt.Errorf("expected 1 protocol, got %d",
+			len(media.Formats[0].Protocols))
+	}
+
+	if media.Formats[0].Protocols[0].Method != "download" {
+		return fmt.Errorf("unknown protocol method: %s",
+			media.Formats[0].Protocols[0].Method)
+	}
+
+	return nil
+}
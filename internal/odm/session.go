@@ -0,0 +1,133 @@
+package odm
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"unicode/utf16"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+// Session holds the OverDrive client/hash handshake and issues the
+// authenticated requests built from it, so the download and early-return
+// flows share one code path instead of each recomputing the license hash.
+type Session struct {
+	Client      *httpclient.Client
+	ClientId    string
+	licenseHash string
+}
+
+func NewSession(client *httpclient.Client) *Session {
+	licenseValue := fmt.Sprintf("%s|%s|%s|%s", ClientId, OMC, OS, HashSecret)
+	encodedLicenseValue := utf16.Encode([]rune(licenseValue))
+
+	hash := sha1.New()
+	binary.Write(hash, binary.LittleEndian, encodedLicenseValue)
+
+	return &Session{
+		Client:      client,
+		ClientId:    ClientId,
+		licenseHash: base64.StdEncoding.EncodeToString(hash.Sum(nil)),
+	}
+}
+
+func (session *Session) query(media Media) url.Values {
+	return url.Values{
+		"MediaID":  []string{media.ContentId},
+		"ClientID": []string{session.ClientId},
+		"OMC":      []string{OMC},
+		"OS":       []string{OS},
+		"Hash":     []string{session.licenseHash},
+	}
+}
+
+// AcquireLicense fetches the license used to authenticate part downloads.
+func (session *Session) AcquireLicense(media Media) ([]byte, error) {
+	acquisitionUrl := media.AcquisitionUrl.Value
+	acquisitionUrl.RawQuery = session.query(media).Encode()
+
+	request, err := http.NewRequest("GET", acquisitionUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("User-Agent", UserAgent)
+
+	request, cancel := session.Client.WithDeadline(request)
+	defer cancel()
+
+	response, err := session.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		responseBody, err := io.ReadAll(response.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("acquiring license returned a %d status: %s",
+			response.StatusCode, responseBody)
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+// DownloadPart builds the authenticated request for a single part, ready to
+// be handed to the download worker pool.
+func (session *Session) DownloadPart(baseUrl string, part Part, license []byte) (*http.Request, error) {
+	partUrl := fmt.Sprintf("%s/%s", baseUrl, part.Filename)
+	request, err := http.NewRequest("GET", partUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("ClientId", session.ClientId)
+	request.Header.Set("License", string(license))
+	request.Header.Set("User-Agent", UserAgent)
+
+	return request, nil
+}
+
+// EarlyReturn releases the loan via the ODM's <EarlyReturnURL>, using the
+// same client/hash handshake as AcquireLicense.
+func (session *Session) EarlyReturn(media Media) error {
+	if media.EarlyReturnUrl.Value == nil {
+		return errors.New("odm file does not contain an early return url")
+	}
+
+	returnUrl := media.EarlyReturnUrl.Value
+	returnUrl.RawQuery = session.query(media).Encode()
+
+	request, err := http.NewRequest("GET", returnUrl.String(), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("User-Agent", UserAgent)
+
+	request, cancel := session.Client.WithDeadline(request)
+	defer cancel()
+
+	response, err := session.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		responseBody, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("early return returned a %d status: %s",
+			response.StatusCode, responseBody)
+	}
+
+	return nil
+}
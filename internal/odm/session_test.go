@@ -0,0 +1,113 @@
+package odm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/arnavdugar/odm/internal/httpclient"
+)
+
+func testMedia(t *testing.T, server *httptest.Server) Media {
+	t.Helper()
+
+	acquisitionUrl, err := url.Parse(server.URL + "/license")
+	if err != nil {
+		t.Fatal(err)
+	}
+	earlyReturnUrl, err := url.Parse(server.URL + "/return")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Media{
+		AcquisitionUrl: Url{Value: acquisitionUrl},
+		EarlyReturnUrl: Url{Value: earlyReturnUrl},
+		ContentId:      "content-id",
+	}
+}
+
+func testSession(t *testing.T) *Session {
+	t.Helper()
+	client, err := httpclient.New(httpclient.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSession(client)
+}
+
+func TestAcquireLicense(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("MediaID") != "content-id" {
+			t.Errorf("got MediaID %q, want %q", r.URL.Query().Get("MediaID"), "content-id")
+		}
+		if r.URL.Query().Get("Hash") == "" {
+			t.Error("expected a Hash query parameter")
+		}
+		w.Write([]byte("license-bytes"))
+	}))
+	defer server.Close()
+
+	session := testSession(t)
+	license, err := session.AcquireLicense(testMedia(t, server))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(license) != "license-bytes" {
+		t.Errorf("got %q, want %q", license, "license-bytes")
+	}
+}
+
+func TestAcquireLicense_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("denied"))
+	}))
+	defer server.Close()
+
+	session := testSession(t)
+	if _, err := session.AcquireLicense(testMedia(t, server)); err == nil {
+		t.Fatal("expected an error for a non-200 response")
+	}
+}
+
+func TestEarlyReturn(t *testing.T) {
+	var returned bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		returned = true
+	}))
+	defer server.Close()
+
+	session := testSession(t)
+	if err := session.EarlyReturn(testMedia(t, server)); err != nil {
+		t.Fatal(err)
+	}
+	if !returned {
+		t.Error("expected the early return endpoint to be hit")
+	}
+}
+
+func TestEarlyReturn_NoUrl(t *testing.T) {
+	session := testSession(t)
+	if err := session.EarlyReturn(Media{ContentId: "content-id"}); err == nil {
+		t.Fatal("expected an error when the odm file has no early return url")
+	}
+}
+
+func TestDownloadPart(t *testing.T) {
+	session := testSession(t)
+	part := Part{Filename: "part1.mp3", Name: "Part 1", Number: 1}
+
+	request, err := session.DownloadPart("http://example.com/base", part, []byte("license-bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "http://example.com/base/part1.mp3"; request.URL.String() != want {
+		t.Errorf("got url %q, want %q", request.URL.String(), want)
+	}
+	if request.Header.Get("License") != "license-bytes" {
+		t.Errorf("got License header %q, want %q", request.Header.Get("License"), "license-bytes")
+	}
+}